@@ -0,0 +1,285 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBackendClosed is returned by AsyncBackend.Log once Flush has
+// been called, whether or not the queue has finished draining.
+var ErrBackendClosed = errors.New("logger: async backend is closed")
+
+// OverflowPolicy controls what an AsyncBackend does when its queue
+// is full.
+type OverflowPolicy int
+
+const (
+	// BlockOnFull makes Log block until the queue has room.
+	BlockOnFull OverflowPolicy = iota
+	// DropOldest discards the oldest queued record to make room for
+	// the new one.
+	DropOldest
+	// DropNewest discards the incoming record, leaving the queue
+	// untouched.
+	DropNewest
+	// DropAndCount behaves like DropNewest, but additionally tracks
+	// how many records have been dropped and periodically emits a
+	// synthetic "dropped N records" log line through the wrapped
+	// backend.
+	DropAndCount
+)
+
+// asyncItem is a queued record together with the level and
+// calldepth its originating Log call was made with. calldepth is
+// only meaningful relative to the goroutine that eventually calls
+// inner.Log, which is a worker, not the producer — see AsyncBackend's
+// doc comment.
+type asyncItem struct {
+	level     Level
+	calldepth int
+	rec       *Record
+}
+
+// AsyncBackend wraps a Backend and dispatches records to it from a
+// pool of worker goroutines via a bounded queue, so that callers on
+// latency-sensitive paths don't block on formatting or I/O.
+//
+// Calldepth is captured in the producer's goroutine at enqueue time,
+// but inner.Log itself always runs later on a worker goroutine with a
+// different, unrelated stack. Any inner backend that resolves the
+// log call's source location via runtime.Caller(calldepth) — a
+// LogBackend configured with log.Lshortfile/log.Llongfile, or
+// moduleLeveled's vmodule/log_backtrace_at if inner ends up being a
+// LeveledBackend below an AsyncBackend — will therefore resolve to a
+// frame inside async.go's worker loop instead of the real call site.
+// Don't put a calldepth-derived backend (directly or via vmodule)
+// below an AsyncBackend.
+type AsyncBackend struct {
+	inner  Backend
+	queue  chan asyncItem
+	policy OverflowPolicy
+
+	// OnEnqueue, if set, is called every time a record is
+	// successfully queued.
+	OnEnqueue func()
+	// OnDrop, if set, is called every time a record is dropped due
+	// to a full queue.
+	OnDrop func()
+	// OnQueueDepth, if set, is called after every enqueue/dequeue
+	// with the current queue depth, as a gauge hook.
+	OnQueueDepth func(depth int)
+
+	// DropReportInterval controls how often a DropAndCount policy
+	// emits its synthetic dropped-records log line. Defaults to one
+	// second.
+	DropReportInterval time.Duration
+
+	dropped      uint64
+	closeOnce    sync.Once
+	reporterDone chan struct{}
+	wg           sync.WaitGroup
+
+	// sendMu serializes Log's send against Flush's close of queue, so
+	// that a send can never race a close and panic: Flush takes
+	// sendMu before closing queue, and Log holds it for the duration
+	// of its send (including the overflow-policy retries).
+	sendMu sync.Mutex
+	closed bool
+}
+
+// NewAsyncBackend returns an AsyncBackend that queues up to
+// queueSize records and dispatches them to inner from workers
+// concurrent goroutines.
+func NewAsyncBackend(inner Backend, queueSize, workers int, policy OverflowPolicy) *AsyncBackend {
+	if workers < 1 {
+		workers = 1
+	}
+	a := &AsyncBackend{
+		inner:              inner,
+		queue:              make(chan asyncItem, queueSize),
+		policy:             policy,
+		DropReportInterval: time.Second,
+	}
+	a.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go a.worker()
+	}
+	if policy == DropAndCount {
+		a.reporterDone = make(chan struct{})
+		go a.reportDrops()
+	}
+	return a
+}
+
+func (a *AsyncBackend) worker() {
+	defer a.wg.Done()
+	for item := range a.queue {
+		a.inner.Log(item.level, item.calldepth, item.rec)
+		a.reportDepth()
+	}
+}
+
+func (a *AsyncBackend) reportDrops() {
+	ticker := time.NewTicker(a.DropReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if n := atomic.SwapUint64(&a.dropped, 0); n > 0 {
+				rec := &Record{
+					Id:     atomic.AddUint64(&sequenceNo, 1),
+					Time:   timeNow(),
+					Module: "logging/async",
+					Level:  WARNING,
+					fmt:    "dropped %d records",
+					args:   []interface{}{n},
+
+					formatter: getFormatter(),
+				}
+				a.inner.Log(WARNING, 1, rec)
+			}
+		case <-a.reporterDone:
+			return
+		}
+	}
+}
+
+// Log queues rec for asynchronous delivery to the wrapped backend,
+// applying the configured OverflowPolicy if the queue is full.
+func (a *AsyncBackend) Log(level Level, calldepth int, rec *Record) error {
+	a.sendMu.Lock()
+	defer a.sendMu.Unlock()
+	if a.closed {
+		return ErrBackendClosed
+	}
+	item := asyncItem{level: level, calldepth: calldepth + 1, rec: rec}
+
+	select {
+	case a.queue <- item:
+		a.enqueued()
+		return nil
+	default:
+	}
+
+	switch a.policy {
+	case BlockOnFull:
+		a.queue <- item
+		a.enqueued()
+		return nil
+	case DropOldest:
+		select {
+		case <-a.queue:
+			a.dropOne()
+		default:
+		}
+		select {
+		case a.queue <- item:
+			a.enqueued()
+		default:
+			a.dropOne()
+		}
+		return nil
+	default: // DropNewest, DropAndCount
+		a.dropOne()
+		return nil
+	}
+}
+
+func (a *AsyncBackend) enqueued() {
+	if a.OnEnqueue != nil {
+		a.OnEnqueue()
+	}
+	a.reportDepth()
+}
+
+func (a *AsyncBackend) dropOne() {
+	if a.policy == DropAndCount {
+		atomic.AddUint64(&a.dropped, 1)
+	}
+	if a.OnDrop != nil {
+		a.OnDrop()
+	}
+}
+
+func (a *AsyncBackend) reportDepth() {
+	if a.OnQueueDepth != nil {
+		a.OnQueueDepth(len(a.queue))
+	}
+}
+
+// Flush stops accepting new records, waits for the queue to drain
+// and all workers to finish, and returns ctx.Err() if ctx expires
+// first.
+func (a *AsyncBackend) Flush(ctx context.Context) error {
+	a.closeOnce.Do(func() {
+		// Hold sendMu while flipping closed and closing queue so that
+		// no Log call can observe closed==false and then lose the
+		// race to send on a channel we're about to close.
+		a.sendMu.Lock()
+		a.closed = true
+		close(a.queue)
+		a.sendMu.Unlock()
+		if a.reporterDone != nil {
+			close(a.reporterDone)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// MultiBackend fans a single record out to several child backends
+// in parallel, so that a slow or blocking backend doesn't delay the
+// others.
+type MultiBackend struct {
+	backends []Backend
+}
+
+// NewMultiBackend returns a Backend that dispatches every record to
+// each of backends.
+func NewMultiBackend(backends ...Backend) *MultiBackend {
+	return &MultiBackend{backends: backends}
+}
+
+func (m *MultiBackend) Log(level Level, calldepth int, rec *Record) error {
+	if len(m.backends) == 0 {
+		return nil
+	}
+	// Force rec's lazily-computed fields to materialize up front:
+	// Message/Formatted both mutate rec in place (redacting args,
+	// caching the rendered line), and rec is shared across all
+	// backends below, so doing this after fanning out would be a
+	// data race.
+	rec.Message()
+	rec.Formatted(calldepth + 1)
+
+	errs := make([]error, len(m.backends))
+	var wg sync.WaitGroup
+	wg.Add(len(m.backends))
+	for i, b := range m.backends {
+		go func(i int, b Backend) {
+			defer wg.Done()
+			errs[i] = b.Log(level, calldepth+1, rec)
+		}(i, b)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"io"
+	"os"
+)
+
+// resolveColor decides whether w should actually receive color
+// escapes, honoring the NO_COLOR and FORCE_COLOR conventions and
+// falling back to a TTY check otherwise. Platform-specific console
+// setup (currently only needed on Windows) happens as a side effect
+// via enableVirtualTerminal.
+func resolveColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("FORCE_COLOR") != "" {
+		if f, ok := w.(*os.File); ok {
+			enableVirtualTerminal(f)
+		}
+		return true
+	}
+	f, ok := w.(*os.File)
+	if !ok || !isTerminal(f) {
+		return false
+	}
+	enableVirtualTerminal(f)
+	return true
+}
+
+// isTerminal reports whether f looks like an interactive terminal,
+// using the portable os.ModeCharDevice check rather than an ioctl
+// through a third-party terminal package, which would be the
+// package's first dependency beyond the standard library.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,9 @@
+// +build !windows
+
+package logging
+
+import "os"
+
+// enableVirtualTerminal is a no-op on platforms that already render
+// ANSI escape sequences natively.
+func enableVirtualTerminal(f *os.File) {}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestResolveColorNoColorWins(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "1")
+	if resolveColor(&bytes.Buffer{}) {
+		t.Error("NO_COLOR should win over FORCE_COLOR")
+	}
+}
+
+func TestResolveColorForceColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+	if !resolveColor(&bytes.Buffer{}) {
+		t.Error("FORCE_COLOR should force color even for a non-TTY writer")
+	}
+}
+
+func TestResolveColorDefaultsToTTYCheck(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	if resolveColor(&bytes.Buffer{}) {
+		t.Error("a bytes.Buffer is not a *os.File and should never get color")
+	}
+}
+
+func TestResolveColorRegularFileIsNotATerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+
+	f, err := os.CreateTemp(t.TempDir(), "color-test")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+	defer f.Close()
+
+	if resolveColor(f) {
+		t.Error("a regular file should not be treated as a terminal")
+	}
+}
+
+func TestIsTerminalRegularFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "color-test")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+	defer f.Close()
+
+	if isTerminal(f) {
+		t.Error("a regular file should not report as a terminal")
+	}
+}
+
+// TestLogBackendStructLiteralGetsColor is a regression test: a
+// LogBackend built as a struct literal (not via NewLogBackend) must
+// still resolve color/TTY detection from its Logger's writer, not
+// from a field only NewLogBackend used to populate.
+func TestLogBackendStructLiteralGetsColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+
+	var buf bytes.Buffer
+	lb := &LogBackend{Logger: log.New(&buf, "", 0), Color: true}
+	if !lb.useColor() {
+		t.Error("a struct-literal LogBackend with Color:true should honor FORCE_COLOR")
+	}
+}
@@ -0,0 +1,27 @@
+// +build windows
+
+package logging
+
+import (
+	"os"
+	"syscall"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+// enableVirtualTerminal turns on ENABLE_VIRTUAL_TERMINAL_PROCESSING
+// for f, so that ANSI escape sequences render correctly on Windows
+// consoles where that mode is off by default instead of as garbage.
+// Consoles that reject the mode (before Windows 10 1511) are left
+// unchanged.
+func enableVirtualTerminal(f *os.File) {
+	handle := syscall.Handle(f.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(handle, &mode); err != nil {
+		return
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return
+	}
+	syscall.SetConsoleMode(handle, mode|enableVirtualTerminalProcessing)
+}
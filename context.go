@@ -0,0 +1,92 @@
+package logging
+
+import "context"
+
+// ctxAnnotaterKey is the context.Context key under which
+// WithAnnotations stores the accumulated Annotater.
+type ctxAnnotaterKey struct{}
+
+// staticAnnotater annotates a Record with a fixed set of
+// Annotations, as collected by WithAnnotations.
+type staticAnnotater struct {
+	annotations []Annotation
+}
+
+func (s *staticAnnotater) Annotate(rec *Record) {
+	rec.Annotations = append(rec.Annotations, s.annotations...)
+}
+
+// WithAnnotations returns a copy of ctx carrying ann in addition to
+// any annotations already attached to ctx, so that request-scoped
+// fields like trace IDs, user IDs, and tenant labels can ride along
+// with a context.Context and be merged into Record.Annotations
+// automatically at emit time via Logger.Ctx.
+func WithAnnotations(ctx context.Context, ann ...Annotation) context.Context {
+	a := Annotater(&staticAnnotater{annotations: ann})
+	if existing := AnnotaterFromContext(ctx); existing != nil {
+		a = stacked{existing, a}
+	}
+	return context.WithValue(ctx, ctxAnnotaterKey{}, a)
+}
+
+// AnnotaterFromContext returns the Annotater accumulated by
+// WithAnnotations, or nil if ctx carries none. This lets existing
+// Re() users continue to stack on top of context-carried
+// annotations.
+func AnnotaterFromContext(ctx context.Context) Annotater {
+	a, _ := ctx.Value(ctxAnnotaterKey{}).(Annotater)
+	return a
+}
+
+// Ctx returns a logger that additionally annotates every record
+// with whatever was attached to ctx via WithAnnotations. If ctx
+// carries no annotations, l is returned unchanged.
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	a := AnnotaterFromContext(ctx)
+	if a == nil {
+		return l
+	}
+	return l.Re(a)
+}
+
+// ctxCalldepth is the calldepth passed to logDepth by every *Ctx
+// method below. The *Ctx methods call logDepth directly, skipping
+// the plain Log() frame that the non-Ctx level methods go through,
+// so this is one less than the calldepth=3 that Log() itself passes.
+const ctxCalldepth = 2
+
+// CriticalCtx logs a message using CRITICAL as log level, annotated
+// with whatever was attached to ctx via WithAnnotations.
+func (l *Logger) CriticalCtx(ctx context.Context, format string, args ...interface{}) {
+	l.Ctx(ctx).logDepth(CRITICAL, ctxCalldepth, format, args...)
+}
+
+// ErrorCtx logs a message using ERROR as log level, annotated with
+// whatever was attached to ctx via WithAnnotations.
+func (l *Logger) ErrorCtx(ctx context.Context, format string, args ...interface{}) {
+	l.Ctx(ctx).logDepth(ERROR, ctxCalldepth, format, args...)
+}
+
+// WarningCtx logs a message using WARNING as log level, annotated
+// with whatever was attached to ctx via WithAnnotations.
+func (l *Logger) WarningCtx(ctx context.Context, format string, args ...interface{}) {
+	l.Ctx(ctx).logDepth(WARNING, ctxCalldepth, format, args...)
+}
+
+// NoticeCtx logs a message using NOTICE as log level, annotated
+// with whatever was attached to ctx via WithAnnotations.
+func (l *Logger) NoticeCtx(ctx context.Context, format string, args ...interface{}) {
+	l.Ctx(ctx).logDepth(NOTICE, ctxCalldepth, format, args...)
+}
+
+// InfoCtx logs a message using INFO as log level, annotated with
+// whatever was attached to ctx via WithAnnotations.
+func (l *Logger) InfoCtx(ctx context.Context, format string, args ...interface{}) {
+	l.Ctx(ctx).logDepth(INFO, ctxCalldepth, format, args...)
+}
+
+// DebugCtx logs a message using DEBUG as log level, annotated with
+// whatever was attached to ctx via WithAnnotations.
+func (l *Logger) DebugCtx(ctx context.Context, format string, args ...interface{}) {
+	l.Ctx(ctx).logDepth(DEBUG, ctxCalldepth, format, args...)
+}
@@ -0,0 +1,126 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+var shortfileRe = regexp.MustCompile(`^([^:]+):(\d+):`)
+
+// parseShortfile extracts the "file:line:" prefix a log.Lshortfile
+// backend prepends to out.
+func parseShortfile(t *testing.T, out string) (file string, line int) {
+	t.Helper()
+	m := shortfileRe.FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("output %q has no file:line prefix", out)
+	}
+	line, err := strconv.Atoi(m[2])
+	if err != nil {
+		t.Fatalf("output %q has a non-numeric line: %v", out, err)
+	}
+	return m[1], line
+}
+
+// newShortfileLogger returns a Logger wired to a LogBackend with
+// log.Lshortfile, using recordingFormatter (defined in async_test.go)
+// so Record.Formatted doesn't depend on the package's default
+// formatter.
+func newShortfileLogger(t *testing.T, buf *bytes.Buffer) *Logger {
+	t.Helper()
+	backend := AddModuleLevel(NewLogBackend(buf, "", log.Lshortfile))
+	ml, ok := backend.(*moduleLeveled)
+	if !ok {
+		t.Fatal("AddModuleLevel did not return a *moduleLeveled for a fresh Backend")
+	}
+	ml.formatter = recordingFormatter{}
+
+	logger := MustGetLogger("calldepth-test")
+	logger.SetBackend(backend)
+	return logger
+}
+
+// TestLogReportsRealCaller is a regression test for the calldepth
+// math in Log/logDepth: plain level methods (Info et al.) must
+// resolve to their caller's file:line under a log.Lshortfile backend,
+// not to a frame inside logger.go.
+func TestLogReportsRealCaller(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newShortfileLogger(t, &buf)
+
+	_, wantFile, wantLine, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("runtime.Caller(0) failed")
+	}
+	logger.Info("plain") // must stay on the line right after runtime.Caller(0)
+	wantLine++
+
+	gotFile, gotLine := parseShortfile(t, buf.String())
+	if filepath.Base(gotFile) != filepath.Base(wantFile) || gotLine != wantLine {
+		t.Fatalf("Info() reported %s:%d, want %s:%d", gotFile, gotLine, filepath.Base(wantFile), wantLine)
+	}
+}
+
+// TestCtxReportsRealCaller is the *Ctx sibling of
+// TestLogReportsRealCaller: the *Ctx methods skip Log()'s frame (they
+// call logDepth directly), so they need one less calldepth than the
+// plain level methods to land on the same caller.
+func TestCtxReportsRealCaller(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newShortfileLogger(t, &buf)
+	ctx := context.Background()
+
+	_, wantFile, wantLine, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("runtime.Caller(0) failed")
+	}
+	logger.InfoCtx(ctx, "with ctx") // must stay on the line right after runtime.Caller(0)
+	wantLine++
+
+	gotFile, gotLine := parseShortfile(t, buf.String())
+	if filepath.Base(gotFile) != filepath.Base(wantFile) || gotLine != wantLine {
+		t.Fatalf("InfoCtx() reported %s:%d, want %s:%d", gotFile, gotLine, filepath.Base(wantFile), wantLine)
+	}
+}
+
+// TestWithAnnotationsPropagate checks that annotations attached via
+// WithAnnotations ride along through Logger.Ctx and stack correctly
+// with annotations from an earlier WithAnnotations call.
+func TestWithAnnotationsPropagate(t *testing.T) {
+	ctx := WithAnnotations(context.Background(), Annotation{Key: "request_id", Value: "r1"})
+	ctx = WithAnnotations(ctx, Annotation{Key: "user_id", Value: "u1"})
+
+	logger := MustGetLogger("annotation-test")
+	annotated := logger.Ctx(ctx)
+	if annotated == logger {
+		t.Fatal("Ctx did not wrap the logger despite context carrying annotations")
+	}
+
+	rec := &Record{}
+	annotated.annotater.Annotate(rec)
+
+	want := map[string]interface{}{"request_id": "r1", "user_id": "u1"}
+	if len(rec.Annotations) != len(want) {
+		t.Fatalf("got %d annotations, want %d: %v", len(rec.Annotations), len(want), rec.Annotations)
+	}
+	for _, a := range rec.Annotations {
+		if want[a.Key] != a.Value {
+			t.Errorf("annotation %q = %v, want %v", a.Key, a.Value, want[a.Key])
+		}
+	}
+}
+
+// TestCtxNoAnnotationsReturnsSameLogger checks the documented
+// fast path: Ctx returns l unchanged when ctx carries no annotations.
+func TestCtxNoAnnotationsReturnsSameLogger(t *testing.T) {
+	logger := MustGetLogger("annotation-test")
+	if got := logger.Ctx(context.Background()); got != logger {
+		t.Fatalf("Ctx(no annotations) returned a different logger")
+	}
+}
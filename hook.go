@@ -0,0 +1,265 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// ErrDropRecord is returned by Hook.Fire to signal that the record
+// should not be passed on to the wrapped backend.
+var ErrDropRecord = errors.New("logger: hook requested dropping this record")
+
+// A Hook observes (and may veto) records passing through a
+// HookBackend. Levels restricts which levels the hook is invoked
+// for; a nil or empty slice means "all levels".
+type Hook interface {
+	Levels() []Level
+	Fire(*Record) error
+}
+
+// HookBackend runs registered hooks before delegating to an inner
+// Backend. Hooks may be registered and unregistered concurrently,
+// and a failing (erroring or panicking) hook never blocks delivery
+// to the inner backend, except via the ErrDropRecord sentinel.
+type HookBackend struct {
+	inner Backend
+
+	mu    sync.RWMutex
+	hooks []Hook
+}
+
+// NewHookBackend returns a HookBackend wrapping inner and running
+// hooks, in order, on every record before it reaches inner.
+func NewHookBackend(inner Backend, hooks ...Hook) *HookBackend {
+	return &HookBackend{inner: inner, hooks: append([]Hook(nil), hooks...)}
+}
+
+// AddHook registers an additional hook.
+func (h *HookBackend) AddHook(hook Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, hook)
+}
+
+// RemoveHook unregisters a previously registered hook.
+func (h *HookBackend) RemoveHook(hook Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, hk := range h.hooks {
+		if hk == hook {
+			h.hooks = append(h.hooks[:i:i], h.hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+func (h *HookBackend) Log(level Level, calldepth int, rec *Record) error {
+	h.mu.RLock()
+	hooks := append([]Hook(nil), h.hooks...)
+	h.mu.RUnlock()
+
+	for _, hk := range hooks {
+		if !levelEnabled(hk.Levels(), level) {
+			continue
+		}
+		if err := fireHook(hk, rec); err == ErrDropRecord {
+			return nil
+		}
+	}
+	return h.inner.Log(level, calldepth+1, rec)
+}
+
+// fireHook invokes hook.Fire, converting a panic into an error so a
+// misbehaving hook can never take down the caller's log statement.
+func fireHook(hook Hook, rec *Record) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("logger: hook panicked: %v", r)
+		}
+	}()
+	return hook.Fire(rec)
+}
+
+func levelEnabled(levels []Level, level Level) bool {
+	if len(levels) == 0 {
+		return true
+	}
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// recordKey hashes a record's module, level and format string
+// together, giving rate-limiting and sampling hooks a stable key
+// per distinct log statement.
+func recordKey(rec *Record) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(rec.Module))
+	h.Write([]byte{'|'})
+	h.Write([]byte(rec.Level.String()))
+	h.Write([]byte{'|'})
+	h.Write([]byte(rec.fmt))
+	return h.Sum64()
+}
+
+// RateLimitHook drops records matching a module+level+message
+// template key once it has been fired more than Rate times per
+// second (with a burst allowance of Burst), using a token bucket per
+// key. This is useful for preventing a single hot log statement from
+// flooding the backend.
+type RateLimitHook struct {
+	// Rate is the steady-state allowed rate, in records per second.
+	Rate float64
+	// Burst is the maximum number of records allowed in a single
+	// instant before the rate limit kicks in.
+	Burst int
+
+	levels  []Level
+	mu      sync.Mutex
+	buckets map[uint64]*tokenBucket
+}
+
+// NewRateLimitHook returns a RateLimitHook allowing up to rate
+// records per second (with the given burst) per distinct
+// module+level+format key, restricted to levels (or all levels, if
+// none are given).
+func NewRateLimitHook(rate float64, burst int, levels ...Level) *RateLimitHook {
+	return &RateLimitHook{
+		Rate:    rate,
+		Burst:   burst,
+		levels:  levels,
+		buckets: make(map[uint64]*tokenBucket),
+	}
+}
+
+func (h *RateLimitHook) Levels() []Level { return h.levels }
+
+func (h *RateLimitHook) Fire(rec *Record) error {
+	key := recordKey(rec)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, ok := h.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(h.Burst), last: timeNow()}
+		h.buckets[key] = b
+	}
+	if !b.take(h.Rate, float64(h.Burst)) {
+		return ErrDropRecord
+	}
+	return nil
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(rate, burst float64) bool {
+	now := timeNow()
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// SamplingHook implements zap-style exponential sampling: within
+// each Tick window, the first First occurrences of a given
+// module+level+format key are allowed through, and thereafter only
+// one in every Thereafter occurrences is.
+type SamplingHook struct {
+	// First is how many occurrences per window are always allowed.
+	First uint64
+	// Thereafter is the sampling rate applied once First has been
+	// exceeded: one in every Thereafter occurrences is allowed.
+	Thereafter uint64
+	// Tick is how often the per-key counters reset. Defaults to one
+	// second.
+	Tick time.Duration
+
+	levels []Level
+	mu     sync.Mutex
+	counts map[uint64]*sampleCounter
+}
+
+type sampleCounter struct {
+	count       uint64
+	windowStart time.Time
+}
+
+// NewSamplingHook returns a SamplingHook allowing the first
+// occurrences per window and one in every occurrences
+// thereafter, per distinct module+level+format key, restricted to
+// levels (or all levels, if none are given).
+func NewSamplingHook(first, thereafter uint64, tick time.Duration, levels ...Level) *SamplingHook {
+	if tick <= 0 {
+		tick = time.Second
+	}
+	return &SamplingHook{
+		First:      first,
+		Thereafter: thereafter,
+		Tick:       tick,
+		levels:     levels,
+		counts:     make(map[uint64]*sampleCounter),
+	}
+}
+
+func (h *SamplingHook) Levels() []Level { return h.levels }
+
+func (h *SamplingHook) Fire(rec *Record) error {
+	key := recordKey(rec)
+	now := timeNow()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c, ok := h.counts[key]
+	if !ok || now.Sub(c.windowStart) >= h.Tick {
+		c = &sampleCounter{windowStart: now}
+		h.counts[key] = c
+	}
+	c.count++
+
+	if c.count <= h.First {
+		return nil
+	}
+	if h.Thereafter > 0 && (c.count-h.First)%h.Thereafter == 0 {
+		return nil
+	}
+	return ErrDropRecord
+}
+
+// MetricsHook increments a user-supplied counter for every record,
+// keyed by (module, level). It never drops records.
+type MetricsHook struct {
+	// Counter is called once per matching record.
+	Counter func(module string, level Level)
+
+	levels []Level
+}
+
+// NewMetricsHook returns a MetricsHook that calls counter for every
+// record at one of levels (or all levels, if none are given).
+func NewMetricsHook(counter func(module string, level Level), levels ...Level) *MetricsHook {
+	return &MetricsHook{Counter: counter, levels: levels}
+}
+
+func (h *MetricsHook) Levels() []Level { return h.levels }
+
+func (h *MetricsHook) Fire(rec *Record) error {
+	if h.Counter != nil {
+		h.Counter(rec.Module, rec.Level)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type fireRecorder struct {
+	name   string
+	levels []Level
+	fired  *[]string
+	err    error
+	panics bool
+}
+
+func (h *fireRecorder) Levels() []Level { return h.levels }
+func (h *fireRecorder) Fire(rec *Record) error {
+	if h.panics {
+		panic("boom")
+	}
+	*h.fired = append(*h.fired, h.name)
+	return h.err
+}
+
+func TestHookBackendRunsHooksInOrderAndFiltersByLevel(t *testing.T) {
+	var fired []string
+	inner := &vmoduleTestBackend{}
+	hb := NewHookBackend(inner,
+		&fireRecorder{name: "all", fired: &fired},
+		&fireRecorder{name: "errors-only", levels: []Level{ERROR}, fired: &fired},
+	)
+
+	hb.Log(INFO, 0, &Record{Level: INFO})
+	if got := fired; len(got) != 1 || got[0] != "all" {
+		t.Fatalf("fired = %v, want [all] for an INFO record", got)
+	}
+
+	fired = nil
+	hb.Log(ERROR, 0, &Record{Level: ERROR})
+	if got := fired; len(got) != 2 || got[0] != "all" || got[1] != "errors-only" {
+		t.Fatalf("fired = %v, want [all errors-only] for an ERROR record", got)
+	}
+}
+
+func TestHookBackendErrDropRecordStopsDelivery(t *testing.T) {
+	var fired []string
+	inner := &vmoduleTestBackend{}
+	hb := NewHookBackend(inner,
+		&fireRecorder{name: "dropper", fired: &fired, err: ErrDropRecord},
+		&fireRecorder{name: "never-runs", fired: &fired},
+	)
+
+	hb.Log(INFO, 0, &Record{Level: INFO})
+	if len(fired) != 1 || fired[0] != "dropper" {
+		t.Fatalf("fired = %v, want only [dropper]", fired)
+	}
+	if inner.last != nil {
+		t.Fatal("record reached inner backend despite ErrDropRecord")
+	}
+}
+
+func TestHookBackendOtherHookErrorStillDelivers(t *testing.T) {
+	inner := &vmoduleTestBackend{}
+	hb := NewHookBackend(inner, &fireRecorder{name: "erroring", fired: &[]string{}, err: errors.New("oops")})
+
+	hb.Log(INFO, 0, &Record{Level: INFO})
+	if inner.last == nil {
+		t.Fatal("a non-ErrDropRecord hook error should not block delivery")
+	}
+}
+
+func TestHookBackendPanicRecovered(t *testing.T) {
+	inner := &vmoduleTestBackend{}
+	hb := NewHookBackend(inner, &fireRecorder{name: "panics", panics: true, fired: &[]string{}})
+
+	hb.Log(INFO, 0, &Record{Level: INFO})
+	if inner.last == nil {
+		t.Fatal("a panicking hook should not prevent delivery to inner")
+	}
+}
+
+func TestHookBackendAddRemoveHook(t *testing.T) {
+	var fired []string
+	inner := &vmoduleTestBackend{}
+	hb := NewHookBackend(inner)
+	h := &fireRecorder{name: "h", fired: &fired}
+	hb.AddHook(h)
+
+	hb.Log(INFO, 0, &Record{Level: INFO})
+	if len(fired) != 1 {
+		t.Fatalf("fired = %v, want one hook to have fired", fired)
+	}
+
+	hb.RemoveHook(h)
+	fired = nil
+	hb.Log(INFO, 0, &Record{Level: INFO})
+	if len(fired) != 0 {
+		t.Fatalf("fired = %v, want no hooks after RemoveHook", fired)
+	}
+}
+
+func TestLevelEnabled(t *testing.T) {
+	if !levelEnabled(nil, ERROR) {
+		t.Error("a nil levels slice should enable every level")
+	}
+	if !levelEnabled([]Level{WARNING, ERROR}, ERROR) {
+		t.Error("ERROR should be enabled by a list containing it")
+	}
+	if levelEnabled([]Level{WARNING}, ERROR) {
+		t.Error("ERROR should not be enabled by a list that omits it")
+	}
+}
+
+func TestRecordKeyStability(t *testing.T) {
+	a := recordKey(&Record{Module: "m", Level: INFO, fmt: "hello %s"})
+	b := recordKey(&Record{Module: "m", Level: INFO, fmt: "hello %s"})
+	if a != b {
+		t.Error("recordKey should be stable for identical module/level/fmt")
+	}
+	if c := recordKey(&Record{Module: "m", Level: ERROR, fmt: "hello %s"}); c == a {
+		t.Error("recordKey should differ when level differs")
+	}
+	if c := recordKey(&Record{Module: "other", Level: INFO, fmt: "hello %s"}); c == a {
+		t.Error("recordKey should differ when module differs")
+	}
+}
+
+func TestRateLimitHookAllowsBurstThenLimits(t *testing.T) {
+	h := NewRateLimitHook(1, 2)
+	rec := &Record{Module: "m", Level: INFO, fmt: "x"}
+
+	if err := h.Fire(rec); err != nil {
+		t.Errorf("1st Fire = %v, want nil (within burst)", err)
+	}
+	if err := h.Fire(rec); err != nil {
+		t.Errorf("2nd Fire = %v, want nil (within burst)", err)
+	}
+	if err := h.Fire(rec); err != ErrDropRecord {
+		t.Errorf("3rd Fire = %v, want ErrDropRecord (burst exhausted)", err)
+	}
+}
+
+func TestRateLimitHookRefillsOverTime(t *testing.T) {
+	h := NewRateLimitHook(100, 1) // 100/sec refill, burst of 1
+	rec := &Record{Module: "m", Level: INFO, fmt: "x"}
+
+	if err := h.Fire(rec); err != nil {
+		t.Fatalf("1st Fire = %v, want nil", err)
+	}
+	if err := h.Fire(rec); err != ErrDropRecord {
+		t.Fatalf("2nd Fire = %v, want ErrDropRecord", err)
+	}
+	time.Sleep(20 * time.Millisecond) // well over one token's worth at 100/sec
+	if err := h.Fire(rec); err != nil {
+		t.Errorf("Fire after refill = %v, want nil", err)
+	}
+}
+
+func TestSamplingHookFirstAndThereafter(t *testing.T) {
+	h := NewSamplingHook(2, 3, time.Hour)
+	rec := &Record{Module: "m", Level: INFO, fmt: "x"}
+
+	want := []bool{true, true, false, false, true, false, false, true}
+	for i, w := range want {
+		allowed := h.Fire(rec) == nil
+		if allowed != w {
+			t.Errorf("occurrence %d: allowed=%v, want %v", i+1, allowed, w)
+		}
+	}
+}
+
+func TestSamplingHookWindowReset(t *testing.T) {
+	h := NewSamplingHook(1, 1000, 20*time.Millisecond)
+	rec := &Record{Module: "m", Level: INFO, fmt: "x"}
+
+	if err := h.Fire(rec); err != nil {
+		t.Fatalf("1st Fire in window = %v, want nil", err)
+	}
+	if err := h.Fire(rec); err != ErrDropRecord {
+		t.Fatalf("2nd Fire in window = %v, want ErrDropRecord", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if err := h.Fire(rec); err != nil {
+		t.Errorf("1st Fire in new window = %v, want nil", err)
+	}
+}
+
+func TestMetricsHookCountsPerModuleLevel(t *testing.T) {
+	type key struct {
+		module string
+		level  Level
+	}
+	counts := map[key]int{}
+	h := NewMetricsHook(func(module string, level Level) {
+		counts[key{module, level}]++
+	})
+
+	h.Fire(&Record{Module: "a", Level: INFO})
+	h.Fire(&Record{Module: "a", Level: INFO})
+	h.Fire(&Record{Module: "b", Level: ERROR})
+
+	if counts[key{"a", INFO}] != 2 {
+		t.Errorf("counts[a,INFO] = %d, want 2", counts[key{"a", INFO}])
+	}
+	if counts[key{"b", ERROR}] != 1 {
+		t.Errorf("counts[b,ERROR] = %d, want 1", counts[key{"b", ERROR}])
+	}
+}
+
+func TestMetricsHookRestrictedToLevels(t *testing.T) {
+	var n int
+	h := NewMetricsHook(func(string, Level) { n++ }, ERROR)
+	if len(h.Levels()) != 1 || h.Levels()[0] != ERROR {
+		t.Fatalf("Levels() = %v, want [ERROR]", h.Levels())
+	}
+}
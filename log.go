@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"sync"
 )
 
 // TODO initialize here
@@ -32,6 +33,9 @@ const (
 type LogBackend struct {
 	Logger *log.Logger
 	Color  bool
+
+	colorOnce    sync.Once
+	colorEnabled bool
 }
 
 // NewLogBackend creates a new LogBackend.
@@ -39,8 +43,29 @@ func NewLogBackend(out io.Writer, prefix string, flag int) *LogBackend {
 	return &LogBackend{Logger: log.New(out, prefix, flag)}
 }
 
+// useColor resolves whether this backend should actually emit color
+// escapes: Color must be set, NO_COLOR must be unset, and (absent a
+// FORCE_COLOR override) the Logger's output must be a TTY. On
+// Windows, the first time color is used against os.Stdout/os.Stderr
+// it also enables ENABLE_VIRTUAL_TERMINAL_PROCESSING, since older
+// consoles otherwise render the escapes as garbage.
+//
+// The writer is read from Logger.Writer() rather than a field
+// populated only by NewLogBackend, so a LogBackend built directly as
+// a struct literal (&LogBackend{Logger: ..., Color: true}) still
+// gets correct color/TTY detection.
+func (b *LogBackend) useColor() bool {
+	if !b.Color {
+		return false
+	}
+	b.colorOnce.Do(func() {
+		b.colorEnabled = resolveColor(b.Logger.Writer())
+	})
+	return b.colorEnabled
+}
+
 func (b *LogBackend) Log(level Level, calldepth int, rec *Record) error {
-	if b.Color {
+	if b.useColor() {
 		buf := &bytes.Buffer{}
 		buf.Write([]byte(colors[level]))
 		buf.Write([]byte(rec.Formatted(calldepth + 1)))
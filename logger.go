@@ -192,6 +192,18 @@ func (l *Logger) IsEnabledFor(level Level) bool {
 }
 
 func (l *Logger) Log(lvl Level, format string, args ...interface{}) {
+	// calldepth=3 brings the stack up to the caller of the level
+	// methods, Info(), Fatal(), etc.: one frame for Log itself, one
+	// for logDepth.
+	l.logDepth(lvl, 3, format, args...)
+}
+
+// logDepth is Log with an explicit calldepth, for wrappers that add
+// their own stack frame (e.g. the *Ctx methods in context.go) and
+// need to adjust for it the same way Output/Outputf already do, so
+// that vmodule/log_backtrace_at and Lshortfile/Llongfile backends
+// still resolve to the real caller instead of the wrapper.
+func (l *Logger) logDepth(lvl Level, calldepth int, format string, args ...interface{}) {
 	// Create the logging record and pass it in to the backend
 	record := &Record{
 		Id:     atomic.AddUint64(&sequenceNo, 1),
@@ -204,12 +216,9 @@ func (l *Logger) Log(lvl Level, format string, args ...interface{}) {
 	if l.annotater != nil {
 		l.annotater.Annotate(record)
 	}
-	// TODO use channels to fan out the records to all backends?
 	// TODO in case of errors, do something (tricky)
 
-	// calldepth=2 brings the stack up to the caller of the level
-	// methods, Info(), Fatal(), etc.
-	l.Backend().Log(lvl, 2, record)
+	l.Backend().Log(lvl, calldepth, record)
 }
 
 func (l *Logger) Print(v ...interface{}) {
@@ -238,7 +247,6 @@ func (l *Logger) Output(calldepth int, s string) error {
 		l.annotater.Annotate(record)
 	}
 
-	// TODO use channels to fan out the records to all backends?
 	// TODO in case of errors, do something (tricky)
 
 	// calldepth=2 brings the stack up to the caller of the level
@@ -259,7 +267,6 @@ func (l *Logger) Outputf(adjdepth int, fmt string, args ...interface{}) {
 		args:   args,
 	}
 
-	// TODO use channels to fan out the records to all backends?
 	// TODO in case of errors, do something (tricky)
 
 	// calldepth=2 brings the stack up to the level of our caller
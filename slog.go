@@ -0,0 +1,169 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// SlogHandler adapts a LeveledBackend to the standard library's
+// log/slog.Handler interface, so records logged through slog flow
+// into this package's backends. Attributes (and group prefixes) are
+// translated into Record.Annotations.
+type SlogHandler struct {
+	backend LeveledBackend
+	module  string
+	group   string
+	attrs   []Annotation
+}
+
+// NewSlogHandler returns a slog.Handler that dispatches records to
+// backend, tagging them with the given module name.
+func NewSlogHandler(backend LeveledBackend, module string) *SlogHandler {
+	return &SlogHandler{
+		backend: backend,
+		module:  module,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.backend.IsEnabledFor(levelFromSlog(level), h.module)
+}
+
+// Handle translates rec into a Record and logs it through the backend.
+func (h *SlogHandler) Handle(_ context.Context, rec slog.Record) error {
+	annotations := make([]Annotation, 0, len(h.attrs)+rec.NumAttrs())
+	annotations = append(annotations, h.attrs...)
+	rec.Attrs(func(a slog.Attr) bool {
+		annotations = append(annotations, h.annotationsFrom(h.group, a)...)
+		return true
+	})
+	record := &Record{
+		Id:          atomic.AddUint64(&sequenceNo, 1),
+		Time:        rec.Time,
+		Module:      h.module,
+		Level:       levelFromSlog(rec.Level),
+		Annotations: annotations,
+		fmt:         "%s",
+		args:        []interface{}{rec.Message},
+	}
+	return h.backend.Log(record.Level, 2, record)
+}
+
+// WithAttrs returns a new handler with attrs merged into every record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = make([]Annotation, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(next.attrs, h.attrs)
+	for _, a := range attrs {
+		next.attrs = append(next.attrs, h.annotationsFrom(h.group, a)...)
+	}
+	return &next
+}
+
+// WithGroup returns a new handler that prefixes subsequent attribute
+// keys with name, joined with dots.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	next := *h
+	if h.group == "" {
+		next.group = name
+	} else {
+		next.group = h.group + "." + name
+	}
+	return &next
+}
+
+func (h *SlogHandler) annotationsFrom(group string, a slog.Attr) []Annotation {
+	if a.Value.Kind() == slog.KindGroup {
+		var out []Annotation
+		sub := a.Key
+		if group != "" {
+			sub = group + "." + a.Key
+		}
+		for _, ga := range a.Value.Group() {
+			out = append(out, h.annotationsFrom(sub, ga)...)
+		}
+		return out
+	}
+	key := a.Key
+	if group != "" {
+		key = group + "." + a.Key
+	}
+	return []Annotation{{Key: key, Value: a.Value.Any()}}
+}
+
+// levelFromSlog maps a slog.Level onto this package's Level scale.
+func levelFromSlog(l slog.Level) Level {
+	switch {
+	case l >= slog.LevelError+4:
+		return CRITICAL
+	case l >= slog.LevelError:
+		return ERROR
+	case l >= slog.LevelWarn:
+		return WARNING
+	case l >= slog.LevelInfo+2:
+		return NOTICE
+	case l >= slog.LevelInfo:
+		return INFO
+	default:
+		return DEBUG
+	}
+}
+
+// levelToSlog maps a Level onto the equivalent slog.Level, inverting
+// levelFromSlog.
+func levelToSlog(l Level) slog.Level {
+	switch l {
+	case CRITICAL:
+		return slog.LevelError + 4
+	case ERROR:
+		return slog.LevelError
+	case WARNING:
+		return slog.LevelWarn
+	case NOTICE:
+		return slog.LevelInfo + 2
+	case INFO:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// slogBackend adapts a slog.Handler to the Backend interface, so
+// that log records produced by this package can be routed through
+// any slog handler (JSON, text, OTel bridges, etc.).
+type slogBackend struct {
+	handler slog.Handler
+}
+
+// NewSlogBackend returns a Backend that forwards records to h.
+func NewSlogBackend(h slog.Handler) Backend {
+	return &slogBackend{handler: h}
+}
+
+func (b *slogBackend) Log(level Level, calldepth int, rec *Record) error {
+	sl := slog.NewRecord(rec.Time, levelToSlog(level), rec.Message(), 0)
+	attrs := make([]slog.Attr, 0, len(rec.Annotations))
+	for _, a := range rec.Annotations {
+		attrs = append(attrs, annotationToSlogAttr(a))
+	}
+	sl.AddAttrs(attrs...)
+	if rec.Module != "" {
+		sl.AddAttrs(slog.String("module", rec.Module))
+	}
+	return b.handler.Handle(context.Background(), sl)
+}
+
+// annotationToSlogAttr converts an Annotation into a slog.Attr,
+// splitting dotted keys back into nested groups.
+func annotationToSlogAttr(a Annotation) slog.Attr {
+	parts := strings.Split(a.Key, ".")
+	key := parts[len(parts)-1]
+	attr := slog.Any(key, a.Value)
+	for i := len(parts) - 2; i >= 0; i-- {
+		attr = slog.Group(parts[i], attr)
+	}
+	return attr
+}
@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+// fakeLeveledBackend is a minimal LeveledBackend that just records
+// the last Record it was given.
+type fakeLeveledBackend struct {
+	last  *Record
+	level Level
+}
+
+func (b *fakeLeveledBackend) Log(level Level, calldepth int, rec *Record) error {
+	b.last = rec
+	return nil
+}
+func (b *fakeLeveledBackend) GetLevel(string) Level          { return b.level }
+func (b *fakeLeveledBackend) SetLevel(level Level, _ string) { b.level = level }
+func (b *fakeLeveledBackend) IsEnabledFor(level Level, _ string) bool {
+	return level <= b.level
+}
+
+func TestLevelFromSlogMapping(t *testing.T) {
+	cases := []struct {
+		in   slog.Level
+		want Level
+	}{
+		{slog.LevelDebug, DEBUG},
+		{slog.LevelInfo, INFO},
+		{slog.LevelInfo + 2, NOTICE},
+		{slog.LevelWarn, WARNING},
+		{slog.LevelError, ERROR},
+		{slog.LevelError + 4, CRITICAL},
+	}
+	for _, c := range cases {
+		if got := levelFromSlog(c.in); got != c.want {
+			t.Errorf("levelFromSlog(%v) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestLevelToSlogRoundTrip(t *testing.T) {
+	for _, l := range []Level{CRITICAL, ERROR, WARNING, NOTICE, INFO, DEBUG} {
+		if got := levelFromSlog(levelToSlog(l)); got != l {
+			t.Errorf("levelFromSlog(levelToSlog(%v)) = %v, want %v", l, got, l)
+		}
+	}
+}
+
+func TestSlogHandlerHandleTranslatesAttrs(t *testing.T) {
+	backend := &fakeLeveledBackend{level: DEBUG}
+	h := NewSlogHandler(backend, "mymod")
+
+	rec := slog.NewRecord(timeNow(), slog.LevelWarn, "hello", 0)
+	rec.AddAttrs(slog.String("key", "value"))
+
+	if err := h.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if backend.last == nil {
+		t.Fatal("backend did not receive a Record")
+	}
+	if backend.last.Module != "mymod" {
+		t.Errorf("Module = %q, want %q", backend.last.Module, "mymod")
+	}
+	if backend.last.Level != WARNING {
+		t.Errorf("Level = %v, want %v", backend.last.Level, WARNING)
+	}
+	if backend.last.Message() != "hello" {
+		t.Errorf("Message() = %q, want %q", backend.last.Message(), "hello")
+	}
+	found := false
+	for _, a := range backend.last.Annotations {
+		if a.Key == "key" && a.Value == "value" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("annotations %v missing key=value", backend.last.Annotations)
+	}
+}
+
+func TestSlogHandlerWithGroupPrefixesKeys(t *testing.T) {
+	backend := &fakeLeveledBackend{level: DEBUG}
+	h := NewSlogHandler(backend, "mymod").WithGroup("req").(*SlogHandler)
+
+	rec := slog.NewRecord(timeNow(), slog.LevelInfo, "hello", 0)
+	rec.AddAttrs(slog.String("id", "123"))
+	if err := h.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if len(backend.last.Annotations) != 1 || backend.last.Annotations[0].Key != "req.id" {
+		t.Fatalf("annotations = %v, want a single req.id key", backend.last.Annotations)
+	}
+}
+
+func TestSlogHandlerWithAttrsPersists(t *testing.T) {
+	backend := &fakeLeveledBackend{level: DEBUG}
+	base := NewSlogHandler(backend, "mymod")
+	withAttrs := base.WithAttrs([]slog.Attr{slog.String("tenant", "acme")}).(*SlogHandler)
+
+	rec := slog.NewRecord(timeNow(), slog.LevelInfo, "hello", 0)
+	if err := withAttrs.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if len(backend.last.Annotations) != 1 || backend.last.Annotations[0].Key != "tenant" {
+		t.Fatalf("annotations = %v, want a single tenant key", backend.last.Annotations)
+	}
+}
+
+// fakeSlogHandler records the last slog.Record it received.
+type fakeSlogHandler struct {
+	last slog.Record
+}
+
+func (h *fakeSlogHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *fakeSlogHandler) Handle(_ context.Context, r slog.Record) error {
+	h.last = r
+	return nil
+}
+func (h *fakeSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler { return h }
+func (h *fakeSlogHandler) WithGroup(name string) slog.Handler       { return h }
+
+func TestSlogBackendForwardsToHandler(t *testing.T) {
+	fake := &fakeSlogHandler{}
+	backend := NewSlogBackend(fake)
+
+	rec := &Record{
+		Module:      "mod",
+		Annotations: []Annotation{{Key: "a.b", Value: "v"}},
+		fmt:         "%s",
+		args:        []interface{}{"hi"},
+	}
+	if err := backend.Log(INFO, 0, rec); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if fake.last.Message != "hi" {
+		t.Errorf("Message = %q, want %q", fake.last.Message, "hi")
+	}
+
+	var gotGroup, gotModule bool
+	fake.last.Attrs(func(a slog.Attr) bool {
+		if a.Key == "a" && a.Value.Kind() == slog.KindGroup {
+			gotGroup = true
+		}
+		if a.Key == "module" {
+			gotModule = true
+		}
+		return true
+	})
+	if !gotGroup {
+		t.Error("expected a nested group attr for dotted annotation key \"a.b\"")
+	}
+	if !gotModule {
+		t.Error("expected a \"module\" attr to be added")
+	}
+}
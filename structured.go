@@ -0,0 +1,222 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LevelEncoder controls how a Level is rendered by the structured
+// formatters.
+type LevelEncoder int
+
+const (
+	// LowercaseLevel renders the level as e.g. "info".
+	LowercaseLevel LevelEncoder = iota
+	// UppercaseLevel renders the level as e.g. "INFO".
+	UppercaseLevel
+	// NumericLevel renders the level as its integer value.
+	NumericLevel
+)
+
+func (e LevelEncoder) encode(level Level) interface{} {
+	switch e {
+	case NumericLevel:
+		return int(level)
+	case LowercaseLevel:
+		return strings.ToLower(level.String())
+	default:
+		return level.String()
+	}
+}
+
+// JSONFormatter renders a Record as a single JSON object containing
+// the standard fields (time, level, module, message, id) plus any
+// Annotations. It implements the Formatter interface.
+type JSONFormatter struct {
+	// TimestampFormat is passed to Time.Format; defaults to
+	// time.RFC3339Nano.
+	TimestampFormat string
+
+	// LevelEncoder controls how the level field is rendered.
+	LevelEncoder LevelEncoder
+
+	// FieldNames optionally renames the standard fields. Any key left
+	// unset (empty string) keeps its default name.
+	FieldNames FieldNames
+
+	// StableKeys sorts annotation keys alphabetically before
+	// encoding, for diff-friendly, reproducible output. When false,
+	// annotations are encoded in Record order.
+	StableKeys bool
+}
+
+// FieldNames overrides the default key names used for the
+// standard (non-annotation) fields emitted by JSONFormatter and
+// LogfmtFormatter.
+type FieldNames struct {
+	Time    string
+	Level   string
+	Module  string
+	Message string
+	Id      string
+}
+
+func (f FieldNames) name(field, def string) string {
+	if field == "" {
+		return def
+	}
+	return field
+}
+
+func (f *JSONFormatter) Format(calldepth int, r *Record, w io.Writer) error {
+	ts := f.TimestampFormat
+	if ts == "" {
+		ts = defaultTimestampFormat
+	}
+
+	keys := []string{
+		f.FieldNames.name(f.FieldNames.Time, "time"),
+		f.FieldNames.name(f.FieldNames.Level, "level"),
+		f.FieldNames.name(f.FieldNames.Module, "module"),
+		f.FieldNames.name(f.FieldNames.Message, "message"),
+		f.FieldNames.name(f.FieldNames.Id, "id"),
+	}
+	values := []interface{}{
+		r.Time.Format(ts),
+		f.LevelEncoder.encode(r.Level),
+		r.Module,
+		r.Message(),
+		r.Id,
+	}
+
+	annotations := r.Annotations
+	if f.StableKeys {
+		annotations = append([]Annotation(nil), r.Annotations...)
+		sort.Slice(annotations, func(i, j int) bool { return annotations[i].Key < annotations[j].Key })
+	}
+	for _, a := range annotations {
+		keys = append(keys, a.Key)
+		values = append(values, jsonValue(a.Value))
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		valJSON, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+		buf.Write(valJSON)
+	}
+	buf.WriteString("}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// LogfmtFormatter renders a Record as a single logfmt line
+// (key=value pairs, space separated) containing the standard fields
+// plus any Annotations. It implements the Formatter interface.
+type LogfmtFormatter struct {
+	// TimestampFormat is passed to Time.Format; defaults to
+	// time.RFC3339Nano.
+	TimestampFormat string
+
+	// LevelEncoder controls how the level field is rendered.
+	LevelEncoder LevelEncoder
+
+	// FieldNames optionally renames the standard fields.
+	FieldNames FieldNames
+
+	// StableKeys sorts annotation keys alphabetically before
+	// encoding, for diff-friendly, reproducible output. When false,
+	// annotations are encoded in Record order.
+	StableKeys bool
+}
+
+func (f *LogfmtFormatter) Format(calldepth int, r *Record, w io.Writer) error {
+	ts := f.TimestampFormat
+	if ts == "" {
+		ts = defaultTimestampFormat
+	}
+
+	buf := &bytes.Buffer{}
+	writePair(buf, f.FieldNames.name(f.FieldNames.Time, "time"), r.Time.Format(ts), true)
+	writePair(buf, f.FieldNames.name(f.FieldNames.Level, "level"), f.LevelEncoder.encode(r.Level), false)
+	writePair(buf, f.FieldNames.name(f.FieldNames.Module, "module"), r.Module, false)
+	writePair(buf, f.FieldNames.name(f.FieldNames.Id, "id"), r.Id, false)
+	writePair(buf, f.FieldNames.name(f.FieldNames.Message, "message"), r.Message(), false)
+
+	annotations := r.Annotations
+	if f.StableKeys {
+		annotations = append([]Annotation(nil), r.Annotations...)
+		sort.Slice(annotations, func(i, j int) bool { return annotations[i].Key < annotations[j].Key })
+	}
+	for _, a := range annotations {
+		writePair(buf, a.Key, a.Value, false)
+	}
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writePair(buf *bytes.Buffer, key string, value interface{}, first bool) {
+	if !first {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(logfmtValue(value))
+}
+
+// jsonValue renders an annotation value the same way logfmtValue
+// does for error and fmt.Stringer: json.Marshal on a bare error (or
+// most Stringers) produces "{}" since they typically expose no
+// fields, silently discarding the message. Everything else is passed
+// through unchanged for json.Marshal to encode normally.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	default:
+		return v
+	}
+}
+
+func logfmtValue(value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case fmt.Stringer:
+		s = v.String()
+	case error:
+		s = v.Error()
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+const defaultTimestampFormat = time.RFC3339Nano
@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stringerValue struct{ s string }
+
+func (v stringerValue) String() string { return v.s }
+
+func newTestRecord(annotations ...Annotation) *Record {
+	return &Record{
+		Id:          7,
+		Time:        time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Module:      "mod",
+		Level:       INFO,
+		Annotations: annotations,
+		fmt:         "hello %s",
+		args:        []interface{}{"world"},
+	}
+}
+
+func TestJSONFormatterRendersErrorAnnotation(t *testing.T) {
+	f := &JSONFormatter{}
+	rec := newTestRecord(Annotation{Key: "err", Value: errors.New("boom")})
+
+	var buf bytes.Buffer
+	if err := f.Format(0, rec, &buf); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output %q did not parse as JSON: %v", buf.String(), err)
+	}
+	if decoded["err"] != "boom" {
+		t.Fatalf(`"err" = %v, want "boom" (got raw output %q)`, decoded["err"], buf.String())
+	}
+}
+
+func TestJSONFormatterRendersStringerAnnotation(t *testing.T) {
+	f := &JSONFormatter{}
+	rec := newTestRecord(Annotation{Key: "thing", Value: stringerValue{"a-thing"}})
+
+	var buf bytes.Buffer
+	if err := f.Format(0, rec, &buf); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output %q did not parse as JSON: %v", buf.String(), err)
+	}
+	if decoded["thing"] != "a-thing" {
+		t.Fatalf(`"thing" = %v, want "a-thing"`, decoded["thing"])
+	}
+}
+
+func TestJSONFormatterStandardFields(t *testing.T) {
+	f := &JSONFormatter{}
+	rec := newTestRecord()
+
+	var buf bytes.Buffer
+	if err := f.Format(0, rec, &buf); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output %q did not parse as JSON: %v", buf.String(), err)
+	}
+	if decoded["message"] != "hello world" {
+		t.Errorf(`"message" = %v, want "hello world"`, decoded["message"])
+	}
+	if decoded["module"] != "mod" {
+		t.Errorf(`"module" = %v, want "mod"`, decoded["module"])
+	}
+	if decoded["level"] != "info" {
+		t.Errorf(`"level" = %v, want "info"`, decoded["level"])
+	}
+}
+
+func TestLogfmtFormatterRendersErrorAnnotation(t *testing.T) {
+	f := &LogfmtFormatter{}
+	rec := newTestRecord(Annotation{Key: "err", Value: errors.New("boom")})
+
+	var buf bytes.Buffer
+	if err := f.Format(0, rec, &buf); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("err=boom")) {
+		t.Fatalf("output %q does not contain err=boom", buf.String())
+	}
+}
+
+func TestLogfmtFormatterQuotesValuesWithSpaces(t *testing.T) {
+	f := &LogfmtFormatter{}
+	rec := newTestRecord(Annotation{Key: "note", Value: "two words"})
+
+	var buf bytes.Buffer
+	if err := f.Format(0, rec, &buf); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`note="two words"`)) {
+		t.Fatalf("output %q does not contain a quoted note field", buf.String())
+	}
+}
+
+func TestStableKeysSortsAnnotations(t *testing.T) {
+	f := &LogfmtFormatter{StableKeys: true}
+	rec := newTestRecord(
+		Annotation{Key: "zebra", Value: "z"},
+		Annotation{Key: "alpha", Value: "a"},
+	)
+
+	var buf bytes.Buffer
+	if err := f.Format(0, rec, &buf); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	out := buf.String()
+	alphaIdx := bytes.Index([]byte(out), []byte("alpha="))
+	zebraIdx := bytes.Index([]byte(out), []byte("zebra="))
+	if alphaIdx < 0 || zebraIdx < 0 || alphaIdx > zebraIdx {
+		t.Fatalf("expected alpha before zebra with StableKeys, got %q", out)
+	}
+}
+
+func TestFieldNamesOverride(t *testing.T) {
+	f := &JSONFormatter{FieldNames: FieldNames{Message: "msg"}}
+	rec := newTestRecord()
+
+	var buf bytes.Buffer
+	if err := f.Format(0, rec, &buf); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output %q did not parse as JSON: %v", buf.String(), err)
+	}
+	if _, ok := decoded["message"]; ok {
+		t.Errorf("default \"message\" key present despite FieldNames override: %q", buf.String())
+	}
+	if decoded["msg"] != "hello world" {
+		t.Errorf(`"msg" = %v, want "hello world"`, decoded["msg"])
+	}
+}
@@ -0,0 +1,182 @@
+package logging
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// vmoduleRule is a single parsed entry from a -vmodule style spec:
+// a glob-ish file pattern (optionally pinned to a single line) and
+// the Level that applies to call sites matching it.
+type vmoduleRule struct {
+	pattern string
+	line    int // 0 means "any line in this file"
+	level   Level
+}
+
+// backtraceLoc is a single parsed entry from SetBacktraceAt: a
+// file pattern and the line it applies to (0 means any line).
+type backtraceLoc struct {
+	pattern string
+	line    int
+}
+
+// callsiteInfo is what we cache per call-site PC: the vmodule
+// level override (if any) and whether a backtrace should be
+// attached.
+type callsiteInfo struct {
+	level     Level
+	hasLevel  bool
+	backtrace bool
+}
+
+// SetVModule configures per-file/line verbosity overrides from a
+// -vmodule style spec, e.g.
+//
+//	gopls/*=4,cmd/server.go=1,internal/foo/bar.go:42=5
+//
+// Each entry is a comma-separated "pattern=level" pair. pattern is
+// matched against the tail path segments of the call site's source
+// file (glob per segment, à la path.Match); it may be pinned to a
+// single line with ":line". level is parsed the same way as
+// LogLevel, or as a bare integer matching the Level's numeric value.
+func (l *moduleLeveled) SetVModule(spec string) error {
+	rules, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+	l.vmoduleMu.Lock()
+	l.vmoduleRules = rules
+	l.vmoduleMu.Unlock()
+	l.invalidateCallerCache()
+	return nil
+}
+
+// SetBacktraceAt configures the set of "file:line" (or bare "file")
+// locations that, when a log record originates from them, get a
+// full goroutine stack trace attached as a "stacktrace" annotation.
+func (l *moduleLeveled) SetBacktraceAt(locations ...string) error {
+	locs := make([]backtraceLoc, 0, len(locations))
+	for _, loc := range locations {
+		pattern, line, err := splitFileLine(loc)
+		if err != nil {
+			return err
+		}
+		locs = append(locs, backtraceLoc{pattern: pattern, line: line})
+	}
+	l.vmoduleMu.Lock()
+	l.backtraceAt = locs
+	l.vmoduleMu.Unlock()
+	l.invalidateCallerCache()
+	return nil
+}
+
+func parseVModule(spec string) ([]vmoduleRule, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	entries := strings.Split(spec, ",")
+	rules := make([]vmoduleRule, 0, len(entries))
+	for _, entry := range entries {
+		eq := strings.LastIndex(entry, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("logger: invalid vmodule entry %q, expected pattern=level", entry)
+		}
+		pattern, line, err := splitFileLine(entry[:eq])
+		if err != nil {
+			return nil, err
+		}
+		level, err := parseVModuleLevel(entry[eq+1:])
+		if err != nil {
+			return nil, fmt.Errorf("logger: invalid vmodule entry %q: %w", entry, err)
+		}
+		rules = append(rules, vmoduleRule{pattern: pattern, line: line, level: level})
+	}
+	return rules, nil
+}
+
+func parseVModuleLevel(s string) (Level, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < int(CRITICAL) || n > int(DEBUG) {
+			return 0, fmt.Errorf("level out of range: %d", n)
+		}
+		return Level(n), nil
+	}
+	return LogLevel(s)
+}
+
+// splitFileLine splits "path/to/file.go:42" into its pattern and
+// line number; a bare "path/to/file.go" yields line 0 ("any line").
+func splitFileLine(s string) (string, int, error) {
+	colon := strings.LastIndex(s, ":")
+	if colon < 0 {
+		return s, 0, nil
+	}
+	line, err := strconv.Atoi(s[colon+1:])
+	if err != nil {
+		// not a "file:line" suffix after all (e.g. a Windows drive
+		// letter); treat the whole thing as the pattern
+		return s, 0, nil
+	}
+	return s[:colon], line, nil
+}
+
+// matchFile reports whether pattern matches the tail path segments
+// of file, matching one segment at a time with path.Match so that
+// "gopls/*" matches ".../gopls/foo.go" and "cmd/server.go" matches
+// only that exact suffix.
+func matchFile(pattern, file string) bool {
+	pSegs := strings.Split(filepath.ToSlash(pattern), "/")
+	fSegs := strings.Split(filepath.ToSlash(file), "/")
+	if len(pSegs) > len(fSegs) {
+		return false
+	}
+	fTail := fSegs[len(fSegs)-len(pSegs):]
+	for i, p := range pSegs {
+		ok, err := path.Match(p, fTail[i])
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupCallsite resolves the vmodule level override and
+// backtrace-at status for the caller calldepth frames up, caching
+// the result per PC so repeated hot-path calls stay cheap.
+func (l *moduleLeveled) lookupCallsite(calldepth int) callsiteInfo {
+	l.vmoduleMu.RLock()
+	vmoduleRules := l.vmoduleRules
+	backtraceAt := l.backtraceAt
+	l.vmoduleMu.RUnlock()
+
+	if len(vmoduleRules) == 0 && len(backtraceAt) == 0 {
+		return callsiteInfo{}
+	}
+	pc, file, line, ok := runtime.Caller(calldepth)
+	if !ok {
+		return callsiteInfo{}
+	}
+	cache := l.callerCache()
+	if v, ok := cache.Load(pc); ok {
+		return v.(callsiteInfo)
+	}
+	info := callsiteInfo{}
+	for _, r := range vmoduleRules {
+		if (r.line == 0 || r.line == line) && matchFile(r.pattern, file) {
+			info.level, info.hasLevel = r.level, true
+		}
+	}
+	for _, b := range backtraceAt {
+		if (b.line == 0 || b.line == line) && matchFile(b.pattern, file) {
+			info.backtrace = true
+			break
+		}
+	}
+	cache.Store(pc, info)
+	return info
+}
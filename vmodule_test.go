@@ -0,0 +1,191 @@
+package logging
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestParseVModule(t *testing.T) {
+	rules, err := parseVModule("gopls/*=4,cmd/server.go=1,internal/foo/bar.go:42=5")
+	if err != nil {
+		t.Fatalf("parseVModule: %v", err)
+	}
+	want := []vmoduleRule{
+		{pattern: "gopls/*", line: 0, level: DEBUG},
+		{pattern: "cmd/server.go", line: 0, level: ERROR},
+		{pattern: "internal/foo/bar.go", line: 42, level: DEBUG},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("got %d rules, want %d: %+v", len(rules), len(want), rules)
+	}
+	for i, r := range rules {
+		if r != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, r, want[i])
+		}
+	}
+}
+
+func TestParseVModuleEmpty(t *testing.T) {
+	rules, err := parseVModule("")
+	if err != nil || rules != nil {
+		t.Fatalf("parseVModule(\"\") = %v, %v, want nil, nil", rules, err)
+	}
+}
+
+func TestParseVModuleInvalidEntry(t *testing.T) {
+	if _, err := parseVModule("no-equals-sign"); err == nil {
+		t.Fatal("expected an error for a malformed entry")
+	}
+}
+
+func TestParseVModuleLevel(t *testing.T) {
+	if l, err := parseVModuleLevel("3"); err != nil || l != NOTICE {
+		t.Errorf("parseVModuleLevel(\"3\") = %v, %v, want NOTICE, nil", l, err)
+	}
+	if l, err := parseVModuleLevel("debug"); err != nil || l != DEBUG {
+		t.Errorf("parseVModuleLevel(\"debug\") = %v, %v, want DEBUG, nil", l, err)
+	}
+	if _, err := parseVModuleLevel("99"); err == nil {
+		t.Error("expected an out-of-range numeric level to error")
+	}
+	if _, err := parseVModuleLevel("not-a-level"); err == nil {
+		t.Error("expected an unrecognized level name to error")
+	}
+}
+
+func TestSplitFileLine(t *testing.T) {
+	cases := []struct {
+		in         string
+		wantFile   string
+		wantLine   int
+		wantErrNil bool
+	}{
+		{"cmd/server.go", "cmd/server.go", 0, true},
+		{"cmd/server.go:42", "cmd/server.go", 42, true},
+		{"C:foo", "C:foo", 0, true}, // not a numeric suffix, treated as a bare pattern
+	}
+	for _, c := range cases {
+		file, line, err := splitFileLine(c.in)
+		if (err == nil) != c.wantErrNil || file != c.wantFile || line != c.wantLine {
+			t.Errorf("splitFileLine(%q) = %q, %d, %v, want %q, %d, nilErr=%v",
+				c.in, file, line, err, c.wantFile, c.wantLine, c.wantErrNil)
+		}
+	}
+}
+
+func TestMatchFile(t *testing.T) {
+	cases := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"gopls/*", "/home/user/src/gopls/foo.go", true},
+		{"gopls/*", "/home/user/src/gopls/sub/foo.go", false},
+		{"cmd/server.go", "/home/user/src/cmd/server.go", true},
+		{"cmd/server.go", "/home/user/src/cmd/other.go", false},
+		{"a/b/c.go", "x/b/c.go", false},
+	}
+	for _, c := range cases {
+		if got := matchFile(c.pattern, c.file); got != c.want {
+			t.Errorf("matchFile(%q, %q) = %v, want %v", c.pattern, c.file, got, c.want)
+		}
+	}
+}
+
+// vmoduleTestBackend is a minimal Backend that just captures the
+// Record it was given.
+type vmoduleTestBackend struct {
+	last *Record
+}
+
+func (b *vmoduleTestBackend) Log(level Level, calldepth int, rec *Record) error {
+	b.last = rec
+	return nil
+}
+
+func TestSetVModuleOverridesLevelAtCallSite(t *testing.T) {
+	inner := &vmoduleTestBackend{}
+	backend := AddModuleLevel(inner)
+	ml := backend.(*moduleLeveled)
+	ml.SetLevel(ERROR, "")
+
+	// With no vmodule rule, DEBUG is above the ERROR threshold and
+	// gets filtered out.
+	rec := &Record{Module: "", Level: DEBUG}
+	backend.Log(DEBUG, 0, rec)
+	if inner.last != nil {
+		t.Fatal("DEBUG record reached the backend despite the ERROR threshold")
+	}
+
+	// A vmodule rule pinned to this file should raise the threshold
+	// for calls originating here, regardless of the module-level
+	// setting.
+	if err := ml.SetVModule("vmodule_test.go=5"); err != nil {
+		t.Fatalf("SetVModule: %v", err)
+	}
+	backend.Log(DEBUG, 0, rec)
+	if inner.last == nil {
+		t.Fatal("DEBUG record was filtered out despite a matching vmodule rule")
+	}
+}
+
+func TestSetBacktraceAtAnnotatesMatchingCallsite(t *testing.T) {
+	inner := &vmoduleTestBackend{}
+	backend := AddModuleLevel(inner)
+	ml := backend.(*moduleLeveled)
+
+	if err := ml.SetBacktraceAt("vmodule_test.go"); err != nil {
+		t.Fatalf("SetBacktraceAt: %v", err)
+	}
+	rec := &Record{Module: "", Level: INFO}
+	backend.Log(INFO, 0, rec)
+	if inner.last == nil {
+		t.Fatal("record did not reach the backend")
+	}
+	found := false
+	for _, a := range inner.last.Annotations {
+		if a.Key == "stacktrace" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a stacktrace annotation for a call site matching SetBacktraceAt")
+	}
+}
+
+// TestVModuleConcurrentReconfigureAndLog exercises SetVModule being
+// reconfigured live while Log calls are in flight from other
+// goroutines; run with -race to catch the field races vmoduleMu
+// guards against.
+func TestVModuleConcurrentReconfigureAndLog(t *testing.T) {
+	inner := &vmoduleTestBackend{}
+	backend := AddModuleLevel(inner)
+	ml := backend.(*moduleLeveled)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			spec := "vmodule_test.go=4"
+			if i%2 == 0 {
+				spec = "vmodule_test.go:1=5"
+			}
+			ml.SetVModule(spec)
+			ml.SetBacktraceAt("vmodule_test.go")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			backend.Log(DEBUG, 0, &Record{Module: "", Level: DEBUG})
+		}
+		close(stop)
+	}()
+	wg.Wait()
+}